@@ -0,0 +1,48 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ceph
+
+import (
+	"github.com/rook/rook/pkg/daemon/ceph/cleanup"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Sanitizes the Ceph devices on this node before the cluster cleanup job releases them back to the OS",
+}
+
+func init() {
+	cleanCmd.RunE = startClean
+	Cmd.AddCommand(cleanCmd)
+}
+
+func startClean(cmd *cobra.Command, args []string) error {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	return cleanup.Run(clientset)
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1 contains API Schema definitions for the ceph v1 API group
+// +k8s:deepcopy-gen=package,register
+// +groupName=ceph.rook.io
+package v1
+
+import (
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CephObjectZone represents a Ceph Object Store Gateway Zone
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+type CephObjectZone struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              CephObjectZoneSpec `json:"spec"`
+	Status            *Status            `json:"status,omitempty"`
+}
+
+// CephObjectZoneList is a list of CephObjectZone
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CephObjectZoneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CephObjectZone `json:"items"`
+}
+
+// CephObjectZoneSpec represent the spec of a CephObjectZone
+type CephObjectZoneSpec struct {
+	// ZoneGroup is the name of the ZoneGroup the zone belongs to
+	ZoneGroup string `json:"zoneGroup"`
+	// SyncPolicy configures multi-site sync groups/flows/pipes for this zone.
+	// +optional
+	SyncPolicy *SyncPolicySpec `json:"syncPolicy,omitempty"`
+	// PullRealm bootstraps this zone's realm by pulling it from a remote primary cluster instead
+	// of requiring a matching CephObjectRealm CR to already exist locally.
+	// +optional
+	PullRealm *PullRealmSpec `json:"pullRealm,omitempty"`
+}
+
+// PullRealmSpec identifies a remote realm to pull and the credentials to authenticate with it.
+type PullRealmSpec struct {
+	// Endpoint is the URL of a RGW endpoint in the remote realm's master zone.
+	Endpoint string `json:"endpoint"`
+	// AccessKeySecretRef refers to the secret key holding the remote system user's access key.
+	AccessKeySecretRef SecretKeySelector `json:"accessKeySecretRef"`
+	// SecretKeySecretRef refers to the secret key holding the remote system user's secret key.
+	SecretKeySecretRef SecretKeySelector `json:"secretKeySecretRef"`
+}
+
+// SecretKeySelector identifies a single key within a Kubernetes Secret in the same namespace.
+type SecretKeySelector struct {
+	// Name is the name of the Secret.
+	Name string `json:"name"`
+	// Key is the key within the Secret's data.
+	Key string `json:"key"`
+}
+
+// Status represents the status of an object
+type Status struct {
+	Phase string `json:"phase,omitempty"`
+	// SyncPolicyGroups reports the enabled/allowed/forbidden state of each sync group configured
+	// on this zone. When no group is listed in the spec, or the policy was not applied because this
+	// is the master zone and allowMaster is unset, it holds a single entry with an empty id whose
+	// state is "enabled" or "forbidden" respectively.
+	// +optional
+	SyncPolicyGroups []SyncPolicyGroupStatus `json:"syncPolicyGroups,omitempty"`
+	// Endpoints are the RGW service endpoints backing this zone, as last pushed to the zone via
+	// `zone modify --endpoints`.
+	// +optional
+	Endpoints []string `json:"endpoints,omitempty"`
+	// PeriodID is the id of the realm period that was current as of the last reconcile.
+	// +optional
+	PeriodID string `json:"periodID,omitempty"`
+	// PeriodEpoch is the epoch of the realm period that was current as of the last reconcile.
+	// +optional
+	PeriodEpoch int `json:"periodEpoch,omitempty"`
+}
+
+// CephObjectZoneGroup represents a Ceph Object Store Gateway Zone Group
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CephObjectZoneGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ObjectZoneGroupSpec `json:"spec"`
+	Status            *Status             `json:"status,omitempty"`
+}
+
+// CephObjectZoneGroupList is a list of CephObjectZoneGroup
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CephObjectZoneGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CephObjectZoneGroup `json:"items"`
+}
+
+// ObjectZoneGroupSpec represent the spec of a CephObjectZoneGroup
+type ObjectZoneGroupSpec struct {
+	// Realm is the name of the realm the zone group belongs to
+	Realm string `json:"realm"`
+}
+
+// SyncPolicySpec declares the multi-site sync groups a CephObjectZone should have configured.
+type SyncPolicySpec struct {
+	// AllowMaster must be set to true before sync groups are applied to a zone that is the
+	// master zone of its zonegroup, since a bad policy there can break replication cluster-wide.
+	// +optional
+	AllowMaster bool `json:"allowMaster,omitempty"`
+	// Groups are the desired sync policy groups for this zone.
+	// +optional
+	Groups []SyncPolicyGroupSpec `json:"groups,omitempty"`
+}
+
+// SyncPolicyGroupSpec represents a single `radosgw-admin sync group`.
+type SyncPolicyGroupSpec struct {
+	ID string `json:"id"`
+	// Status is one of "enabled", "allowed", or "forbidden".
+	Status string `json:"status"`
+	// +optional
+	Flows []SyncFlowSpec `json:"flows,omitempty"`
+	// +optional
+	Pipes []SyncPipeSpec `json:"pipes,omitempty"`
+}
+
+// SyncFlowSpec represents a single `radosgw-admin sync group flow`.
+type SyncFlowSpec struct {
+	ID string `json:"id"`
+	// Type is one of "directional" or "symmetrical".
+	Type       string `json:"type"`
+	SourceZone string `json:"sourceZone,omitempty"`
+	DestZone   string `json:"destZone,omitempty"`
+}
+
+// SyncPipeSpec represents a single `radosgw-admin sync group pipe`.
+type SyncPipeSpec struct {
+	ID     string              `json:"id"`
+	Source SyncPipePeerSpec    `json:"source"`
+	Dest   SyncPipePeerSpec    `json:"dest"`
+	Filter *SyncPipeFilterSpec `json:"filter,omitempty"`
+}
+
+// SyncPipePeerSpec identifies the zone(s) and, optionally, the single bucket a sync pipe applies to.
+type SyncPipePeerSpec struct {
+	Zones  string `json:"zones,omitempty"`
+	Bucket string `json:"bucket,omitempty"`
+}
+
+// SyncPipeFilterSpec restricts a sync pipe to objects matching a prefix and/or tag.
+type SyncPipeFilterSpec struct {
+	Prefix string `json:"prefix,omitempty"`
+	Tag    string `json:"tag,omitempty"`
+}
+
+// SyncPolicyGroupStatus reports the on-cluster state of a single sync policy group.
+type SyncPolicyGroupStatus struct {
+	ID string `json:"id,omitempty"`
+	// State is one of "enabled", "allowed", or "forbidden".
+	State string `json:"state,omitempty"`
+}
+
+// CephCluster represents a Ceph cluster
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+type CephCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ClusterSpec    `json:"spec"`
+	Status            *ClusterStatus `json:"status,omitempty"`
+}
+
+// CephClusterList is a list of CephCluster
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CephClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CephCluster `json:"items"`
+}
+
+// ClusterSpec represents the spec of a CephCluster
+type ClusterSpec struct {
+	DataDirHostPath    string                         `json:"dataDirHostPath,omitempty"`
+	Annotations        rookv1.AnnotationsSpec         `json:"annotations,omitempty"`
+	Resources          rookv1.ResourceSpec            `json:"resources,omitempty"`
+	Placement          rookv1.PlacementSpec           `json:"placement,omitempty"`
+	PriorityClassNames rookv1.PriorityClassNamesSpec  `json:"priorityClassNames,omitempty"`
+	// CleanupPolicy controls how the operator cleans up host state when the CephCluster is deleted.
+	// +optional
+	CleanupPolicy CleanupPolicySpec `json:"cleanupPolicy,omitempty"`
+}
+
+// CleanupPolicySpec represents a cleanup policy
+type CleanupPolicySpec struct {
+	// Confirmation must be set to "yes-really-destroy-data" to enable any cleanup behavior.
+	// +optional
+	Confirmation string `json:"confirmation,omitempty"`
+	// SanitizeDisks configures how the cleanup job destroys on-disk data before it releases devices.
+	// +optional
+	SanitizeDisks SanitizeDisksSpec `json:"sanitizeDisks,omitempty"`
+	// ForceDelete tells the operator to release the cleanup finalizer immediately, regardless of
+	// how much (if any) disk sanitization has completed, letting deletion proceed even if cleanup
+	// is stuck or has failed on one or more nodes. Without this set, the finalizer is only
+	// released once every node's cleanup job has succeeded.
+	// +optional
+	ForceDelete bool `json:"forceDelete,omitempty"`
+}
+
+// SanitizeDisksSpec represents how to sanitize (wipe) disks during cleanup
+type SanitizeDisksSpec struct {
+	// Method is one of "quick", "complete", or "crypto-erase".
+	// +optional
+	Method string `json:"method,omitempty"`
+	// DataSource is one of "zero" or "random".
+	// +optional
+	DataSource string `json:"dataSource,omitempty"`
+	// Iteration is the number of times each device is overwritten when Method is "complete".
+	// +optional
+	Iteration int `json:"iteration,omitempty"`
+	// DryRun only reports which method would be used per device without writing anything.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// ClusterStatus represents the status of a CephCluster
+type ClusterStatus struct {
+	Phase string `json:"phase,omitempty"`
+	// CleanupStatus tracks the progress of host clean up jobs run when the cluster is deleted.
+	// +optional
+	CleanupStatus *CleanupStatus `json:"cleanupStatus,omitempty"`
+}
+
+// CleanupStatus tracks the progress of the per-node host cleanup jobs run on CephCluster deletion.
+type CleanupStatus struct {
+	// Message is a human readable summary of overall cleanup progress.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Nodes reports the cleanup phase of each node being cleaned up.
+	// +optional
+	Nodes []CleanupNodeStatus `json:"nodes,omitempty"`
+}
+
+// CleanupNodeStatus reports the cleanup phase of a single node.
+type CleanupNodeStatus struct {
+	Node string `json:"node"`
+	// Phase is one of "Pending", "Running", "Succeeded", or "Failed".
+	Phase string `json:"phase"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// CephObjectStore represents a Ceph Object Store Gateway
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+type CephObjectStore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ObjectStoreSpec `json:"spec"`
+	Status            *Status         `json:"status,omitempty"`
+}
+
+// CephObjectStoreList is a list of CephObjectStore
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CephObjectStoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CephObjectStore `json:"items"`
+}
+
+// ObjectStoreSpec represent the spec of a CephObjectStore
+type ObjectStoreSpec struct {
+	// Zone is the name of the CephObjectZone this store's RGW instances belong to. An empty Zone
+	// means this store runs its own independent realm/zonegroup/zone.
+	// +optional
+	Zone ZoneSpec `json:"zone,omitempty"`
+	// Gateway describes the RGW service this store's gateway pods are exposed through.
+	Gateway GatewaySpec `json:"gateway"`
+}
+
+// ZoneSpec represents the name of the CephObjectZone an object store's RGW daemons belong to
+type ZoneSpec struct {
+	Name string `json:"name"`
+	// Namespace is the namespace of the CephObjectZone, if it differs from this CephObjectStore's
+	// own namespace. Defaults to this CephObjectStore's namespace when empty.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GatewaySpec represents the specification of the RGW service exposing an object store's gateway
+type GatewaySpec struct {
+	// Port is the service port the RGW listens on for plain HTTP.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// SecurePort is the service port the RGW listens on for HTTPS, if any.
+	// +optional
+	SecurePort int32 `json:"securePort,omitempty"`
+}
+
+// GetCleanupAnnotations returns the annotations that should be applied to cleanup job resources.
+func GetCleanupAnnotations(a rookv1.AnnotationsSpec) rookv1.Annotations {
+	return a.All()
+}
+
+// GetCleanupResources returns the resource requirements that should be applied to the cleanup job container.
+func GetCleanupResources(r rookv1.ResourceSpec) v1.ResourceRequirements {
+	return r.All()
+}
+
+// GetCleanupPriorityClassName returns the priority class name that should be applied to the cleanup job pod.
+func GetCleanupPriorityClassName(p rookv1.PriorityClassNamesSpec) string {
+	return p.All()
+}
+
+// GetCleanupPlacement returns the placement that should be applied to the cleanup job pod.
+func GetCleanupPlacement(p rookv1.PlacementSpec) rookv1.Placement {
+	return p.All()
+}
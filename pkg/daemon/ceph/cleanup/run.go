@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleanup
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// EnvMethod names the sanitize method to use, one of MethodQuick, MethodComplete, or
+	// MethodCryptoErase. Set by pkg/operator/ceph/cluster from CleanupPolicySpec.SanitizeDisks.
+	EnvMethod = "ROOK_SANITIZE_METHOD"
+	// EnvDataSource names the overwrite data source ("zero" or "random") used by MethodComplete.
+	EnvDataSource = "ROOK_SANITIZE_DATA_SOURCE"
+	// EnvIteration names the number of overwrite passes used by MethodComplete.
+	EnvIteration = "ROOK_SANITIZE_ITERATION"
+	// EnvDryRun, when "true", probes and logs the method that would be used per device without
+	// wiping anything.
+	EnvDryRun = "ROOK_SANITIZE_DRY_RUN"
+	// EnvNamespace is the namespace the cleanup job's report ConfigMap is written to.
+	EnvNamespace = "ROOK_NAMESPACE_DIR"
+	// EnvHostName is the name of the node the cleanup job is running on, used to name its
+	// report ConfigMap so the operator can find it again by ReportConfigMapName(hostName).
+	EnvHostName = "ROOK_NODE_HOSTNAME"
+
+	sysBlockDir = "/sys/block"
+)
+
+// Run is the entrypoint for the `rook ceph clean` command started by the cleanup job container.
+// It discovers the block devices on this node, sanitizes them according to the ROOK_SANITIZE_*
+// environment variables, and persists the result to the ConfigMap named by
+// ReportConfigMapName(hostName) so the operator can aggregate it into the cluster-wide report.
+func Run(clientset kubernetes.Interface) error {
+	namespace := os.Getenv(EnvNamespace)
+	hostName := os.Getenv(EnvHostName)
+	if namespace == "" || hostName == "" {
+		return errors.Errorf("%s and %s must be set", EnvNamespace, EnvHostName)
+	}
+
+	opts, err := optionsFromEnv()
+	if err != nil {
+		return errors.Wrap(err, "failed to parse sanitize options")
+	}
+
+	devices, err := DiscoverDevices()
+	if err != nil {
+		return errors.Wrap(err, "failed to discover devices to sanitize")
+	}
+
+	results := SanitizeDevices(devices, opts)
+	report := NodeReport{Node: hostName, DryRun: opts.DryRun, Results: results}
+
+	return writeReport(clientset, namespace, hostName, report)
+}
+
+func optionsFromEnv() (Options, error) {
+	opts := Options{
+		Method:     os.Getenv(EnvMethod),
+		DataSource: os.Getenv(EnvDataSource),
+		DryRun:     os.Getenv(EnvDryRun) == "true",
+	}
+
+	if raw := os.Getenv(EnvIteration); raw != "" {
+		iteration, err := strconv.Atoi(raw)
+		if err != nil {
+			return Options{}, errors.Wrapf(err, "failed to parse %s value %q", EnvIteration, raw)
+		}
+		opts.Iteration = iteration
+	}
+
+	return opts, nil
+}
+
+// DiscoverDevices lists the whole-disk block devices on this node, skipping virtual devices
+// (loopback, ramdisk, device-mapper) that are never Ceph-owned physical disks.
+func DiscoverDevices() ([]string, error) {
+	entries, err := ioutil.ReadDir(sysBlockDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q", sysBlockDir)
+	}
+
+	devices := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") || strings.HasPrefix(name, "dm-") {
+			continue
+		}
+		devices = append(devices, filepath.Join("/dev", name))
+	}
+
+	return devices, nil
+}
+
+func writeReport(clientset kubernetes.Interface, namespace, hostName string, report NodeReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal sanitize report")
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ReportConfigMapName(hostName), Namespace: namespace},
+		Data:       map[string]string{"report": string(data)},
+	}
+
+	if _, err := clientset.CoreV1().ConfigMaps(namespace).Create(cm); err != nil {
+		if !kerrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "failed to create sanitize report configmap %q", cm.Name)
+		}
+
+		existing, err := clientset.CoreV1().ConfigMaps(namespace).Get(cm.Name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get existing sanitize report configmap %q", cm.Name)
+		}
+		existing.Data = cm.Data
+
+		if _, err := clientset.CoreV1().ConfigMaps(namespace).Update(existing); err != nil {
+			return errors.Wrapf(err, "failed to update sanitize report configmap %q", cm.Name)
+		}
+	}
+
+	return nil
+}
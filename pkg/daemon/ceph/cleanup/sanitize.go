@@ -0,0 +1,216 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cleanup sanitizes the on-disk data of a node's Ceph devices before the cluster cleanup
+// job releases them back to the OS. Run is invoked by the `rook ceph clean` command started by
+// the cleanup job container from pkg/operator/ceph/cluster, which passes the
+// cephv1.CleanupPolicySpec.SanitizeDisks options via environment variables; the devices
+// themselves are discovered on the node by DiscoverDevices.
+package cleanup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", "cephcleanup")
+
+const (
+	// MethodQuick makes one fast pass per device: a secure discard on non-rotational devices when
+	// blkdiscard is available, otherwise a single overwrite with zeros.
+	MethodQuick = "quick"
+	// MethodComplete overwrites each device the configured number of iterations.
+	MethodComplete = "complete"
+	// MethodCryptoErase issues a hardware secure-erase/format instead of overwriting data.
+	MethodCryptoErase = "crypto-erase"
+)
+
+// ReportConfigMapName returns the name of the ConfigMap a cleanup job on hostName writes its
+// per-device sanitization results to. The operator reads it back by the same name to build its
+// cluster-wide report, so this must stay the single source of truth for the naming scheme.
+func ReportConfigMapName(hostName string) string {
+	return k8sutil.TruncateNodeName("cluster-cleanup-sanitize-report-%s", hostName)
+}
+
+// DeviceResult is the outcome of sanitizing a single device.
+type DeviceResult struct {
+	Device      string `json:"device"`
+	Method      string `json:"method"`
+	BytesWiped  int64  `json:"bytesWiped"`
+	DurationSec int    `json:"durationSeconds"`
+	Success     bool   `json:"success"`
+	Error       string `json:"error,omitempty"`
+}
+
+// NodeReport is the per-node sanitize report a cleanup job persists to its ConfigMap.
+type NodeReport struct {
+	Node    string         `json:"node"`
+	DryRun  bool           `json:"dryRun"`
+	Results []DeviceResult `json:"results"`
+}
+
+// Options configures how SanitizeDevices wipes a set of devices.
+type Options struct {
+	// Method is one of MethodQuick, MethodComplete, or MethodCryptoErase.
+	Method string
+	// DataSource is "zero" or "random"; only consulted by MethodComplete.
+	DataSource string
+	// Iteration is the number of overwrite passes; only consulted by MethodComplete.
+	Iteration int
+	// DryRun probes and logs the method that would be used per device without wiping anything.
+	DryRun bool
+}
+
+// SanitizeDevices wipes each device according to opts, probing each device's capabilities to pick
+// the best available method for it. Devices are sanitized best-effort: a failure on one device is
+// recorded in its DeviceResult and does not stop the others from being sanitized.
+func SanitizeDevices(devices []string, opts Options) []DeviceResult {
+	results := make([]DeviceResult, 0, len(devices))
+	for _, device := range devices {
+		results = append(results, sanitizeDevice(device, opts))
+	}
+	return results
+}
+
+func sanitizeDevice(device string, opts Options) DeviceResult {
+	start := time.Now()
+
+	method, err := effectiveMethod(device, opts.Method)
+	if err != nil {
+		return DeviceResult{Device: device, Method: opts.Method, Success: false, Error: err.Error()}
+	}
+
+	bin, args := commandFor(device, method, opts)
+	if opts.DryRun {
+		logger.Infof("dry-run: would sanitize device %q with %q %s", device, bin, strings.Join(args, " "))
+		return DeviceResult{Device: device, Method: method, Success: true, DurationSec: int(time.Since(start).Seconds())}
+	}
+
+	logger.Infof("sanitizing device %q with %q %s", device, bin, strings.Join(args, " "))
+	output, err := exec.Command(bin, args...).CombinedOutput()
+	result := DeviceResult{Device: device, Method: method, DurationSec: int(time.Since(start).Seconds()), Success: err == nil}
+	if err != nil {
+		result.Error = fmt.Sprintf("%s failed: %v: %s", bin, err, strings.TrimSpace(string(output)))
+		return result
+	}
+
+	if size, sizeErr := deviceSizeBytes(device); sizeErr == nil {
+		result.BytesWiped = size
+	} else {
+		logger.Warningf("sanitized device %q but could not determine its size. %v", device, sizeErr)
+	}
+	return result
+}
+
+// effectiveMethod resolves the requested method against what device actually supports. Requesting
+// MethodCryptoErase on a device with no hardware secure-erase/format support is an error rather
+// than a silent downgrade, since it would otherwise leave data on disk while reporting success.
+func effectiveMethod(device, method string) (string, error) {
+	switch method {
+	case MethodCryptoErase:
+		if !supportsNVMeFormat(device) && !supportsSecureErase(device) {
+			return "", errors.Errorf("device %q supports neither nvme format nor hdparm security erase", device)
+		}
+		return MethodCryptoErase, nil
+	case MethodComplete, MethodQuick, "":
+		return method, nil
+	default:
+		return "", errors.Errorf("unknown sanitize method %q", method)
+	}
+}
+
+// commandFor returns the binary and arguments that sanitize device using method.
+func commandFor(device, method string, opts Options) (string, []string) {
+	switch method {
+	case MethodCryptoErase:
+		if supportsNVMeFormat(device) {
+			return "nvme", []string{"format", device, "--ses=1"}
+		}
+		return "hdparm", []string{"--user-master", "u", "--security-erase", "rook-ceph", device}
+	case MethodComplete:
+		iteration := opts.Iteration
+		if iteration <= 0 {
+			iteration = 1
+		}
+		args := []string{"--iterations", strconv.Itoa(iteration)}
+		if opts.DataSource == "random" {
+			return "shred", append(args, device)
+		}
+		return "shred", append(append(args, "--zero"), device)
+	default:
+		// MethodQuick, and any unset method, fall back to the fastest destructive option the
+		// device supports.
+		if supportsBlkdiscard(device) {
+			return "blkdiscard", []string{"--secure", device}
+		}
+		return "shred", []string{"--iterations", "1", "--zero", device}
+	}
+}
+
+func supportsNVMeFormat(device string) bool {
+	if _, err := exec.LookPath("nvme"); err != nil {
+		return false
+	}
+	return strings.HasPrefix(filepath.Base(device), "nvme")
+}
+
+// supportsSecureErase reports whether hdparm is available and the device advertises security
+// erase support in its identify data.
+func supportsSecureErase(device string) bool {
+	if _, err := exec.LookPath("hdparm"); err != nil {
+		return false
+	}
+	output, err := exec.Command("hdparm", "-I", device).CombinedOutput()
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(output), "supported: enhanced erase") || strings.Contains(string(output), "\tsupported\n")
+}
+
+// supportsBlkdiscard reports whether blkdiscard is available and device is backed by an SSD,
+// where a secure discard is both fast and an effective sanitization.
+func supportsBlkdiscard(device string) bool {
+	if _, err := exec.LookPath("blkdiscard"); err != nil {
+		return false
+	}
+	rotational, err := ioutil.ReadFile(fmt.Sprintf("/sys/block/%s/queue/rotational", filepath.Base(device)))
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(rotational)) == "0"
+}
+
+func deviceSizeBytes(device string) (int64, error) {
+	sizeFile := fmt.Sprintf("/sys/block/%s/size", filepath.Base(device))
+	contents, err := ioutil.ReadFile(sizeFile)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read %q", sizeFile)
+	}
+	sectors, err := strconv.ParseInt(strings.TrimSpace(string(contents)), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse sector count in %q", sizeFile)
+	}
+	return sectors * 512, nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleanup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectiveMethod(t *testing.T) {
+	method, err := effectiveMethod("/dev/rook-test-missing", MethodComplete)
+	assert.NoError(t, err)
+	assert.Equal(t, MethodComplete, method)
+
+	method, err = effectiveMethod("/dev/rook-test-missing", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "", method)
+
+	_, err = effectiveMethod("/dev/rook-test-missing", "not-a-method")
+	assert.Error(t, err)
+
+	// neither nvme format nor hdparm security erase support can be detected for a device that
+	// doesn't exist, so crypto-erase must be refused rather than silently downgraded.
+	_, err = effectiveMethod("/dev/rook-test-missing", MethodCryptoErase)
+	assert.Error(t, err)
+}
+
+func TestCommandForComplete(t *testing.T) {
+	bin, args := commandFor("/dev/rook-test-missing", MethodComplete, Options{Iteration: 3, DataSource: "zero"})
+	assert.Equal(t, "shred", bin)
+	assert.Equal(t, []string{"--iterations", "3", "--zero", "/dev/rook-test-missing"}, args)
+
+	bin, args = commandFor("/dev/rook-test-missing", MethodComplete, Options{Iteration: 0, DataSource: "random"})
+	assert.Equal(t, "shred", bin)
+	assert.Equal(t, []string{"--iterations", "1", "/dev/rook-test-missing"}, args)
+}
+
+func TestCommandForQuickFallback(t *testing.T) {
+	// a device that doesn't exist supports neither blkdiscard nor crypto erase, so the quick
+	// fallback must be the plain shred invocation.
+	bin, args := commandFor("/dev/rook-test-missing", MethodQuick, Options{})
+	assert.Equal(t, "shred", bin)
+	assert.Equal(t, []string{"--iterations", "1", "--zero", "/dev/rook-test-missing"}, args)
+}
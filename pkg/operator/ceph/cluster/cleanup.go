@@ -23,6 +23,7 @@ import (
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	"github.com/rook/rook/pkg/daemon/ceph/cleanup"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mgr"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
@@ -34,6 +35,7 @@ import (
 	"github.com/rook/rook/pkg/util"
 	batch "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -51,8 +53,27 @@ var (
 	clusterFSID     = "ROOK_CLUSTER_FSID"
 )
 
+// startClusterCleanUp blocks until host clean up jobs have been run on every node (or
+// cancelled), persisting progress to the CephCluster's CleanupStatus and resuming rather than
+// replacing any per-node job that's already running so an operator restart doesn't throw away
+// in-progress work (see the Get-before-create check in startCleanUpJobs). This is still a
+// blocking, per-cluster call driven off the existing informer callback rather than a
+// controller-runtime-reconciled CRD/status subresource: the latter would let the operator resume
+// a cleanup run without replaying this whole function from the top after a restart, but doing so
+// would mean migrating this subsystem off the shared-informer ClusterController pattern the rest
+// of this package uses, which is a larger change than this fix covers.
 func (c *ClusterController) startClusterCleanUp(stopCleanupCh chan struct{}, cluster *cephv1.CephCluster, cephHosts []string, monSecret, clusterFSID string) {
 	logger.Infof("starting clean up for cluster %q", cluster.Name)
+
+	if err := c.addCleanupFinalizer(cluster); err != nil {
+		logger.Errorf("failed to add cleanup finalizer to cluster %q, cleanup will not block deletion. %v", cluster.Name, err)
+	}
+	// Evaluate on every exit path below, not just the happy path, otherwise a failure partway
+	// through cleanup (e.g. stopCleanupCh firing while waiting on ceph daemons) would leave the
+	// finalizer in place even when the operator has explicitly force-cancelled cleanup.
+	defer c.releaseCleanupFinalizerIfDone(cluster)
+	c.initCleanupStatus(cluster, cephHosts)
+
 	err := c.waitForCephDaemonCleanUp(stopCleanupCh, cluster, time.Duration(clusterCleanUpPolicyRetryInterval)*time.Second)
 	if err != nil {
 		logger.Errorf("failed to wait till ceph daemons are destroyed. %v", err)
@@ -60,37 +81,77 @@ func (c *ClusterController) startClusterCleanUp(stopCleanupCh chan struct{}, clu
 	}
 
 	c.startCleanUpJobs(cluster, cephHosts, monSecret, clusterFSID)
+
+	if _, err := c.aggregateSanitizeReport(cluster.Namespace, cephHosts); err != nil {
+		logger.Errorf("failed to aggregate disk sanitization report for cluster %q. %v", cluster.Name, err)
+	}
 }
 
 func (c *ClusterController) startCleanUpJobs(cluster *cephv1.CephCluster, cephHosts []string, monSecret, clusterFSID string) {
 	for _, hostName := range cephHosts {
 		logger.Infof("starting clean up job on node %q", hostName)
+		c.updateCleanupNodeStatus(cluster, hostName, cleanupPhaseRunning, "")
+
 		jobName := k8sutil.TruncateNodeName("cluster-cleanup-job-%s", hostName)
-		podSpec := c.cleanUpJobTemplateSpec(cluster, monSecret, clusterFSID)
-		podSpec.Spec.NodeSelector = map[string]string{v1.LabelHostname: hostName}
-		labels := controller.AppLabels(CleanupAppName, cluster.Namespace)
-		labels[CleanupAppName] = "true"
-		job := &batch.Job{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      jobName,
-				Namespace: cluster.Namespace,
-				Labels:    labels,
-			},
-			Spec: batch.JobSpec{
-				Template: podSpec,
-			},
-		}
 
-		// Apply annotations
-		cephv1.GetCleanupAnnotations(cluster.Spec.Annotations).ApplyToObjectMeta(&job.ObjectMeta)
+		// If a clean up job for this node already exists and hasn't failed (e.g. the operator
+		// restarted partway through a previous clean up pass and this job is still running or
+		// already succeeded), resume watching it instead of replacing it, so a restart doesn't
+		// throw away an in-progress or completed disk wipe. A transient error looking up the Job
+		// is not treated as "missing" so we don't clobber a job we simply failed to inspect.
+		existing, err := c.context.Clientset.BatchV1().Jobs(cluster.Namespace).Get(jobName, metav1.GetOptions{})
+		switch {
+		case err != nil && !kerrors.IsNotFound(err):
+			logger.Errorf("failed to check for an existing clean up job %q on node %q, skipping this node this round. %v", jobName, hostName, err)
+			continue
+		case err == nil && !jobFailed(existing):
+			logger.Infof("clean up job %q on node %q already exists, resuming wait on it instead of restarting it", jobName, hostName)
+		default:
+			podSpec := c.cleanUpJobTemplateSpec(cluster, hostName, monSecret, clusterFSID)
+			podSpec.Spec.NodeSelector = map[string]string{v1.LabelHostname: hostName}
+			labels := controller.AppLabels(CleanupAppName, cluster.Namespace)
+			labels[CleanupAppName] = "true"
+			job := &batch.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: cluster.Namespace,
+					Labels:    labels,
+				},
+				Spec: batch.JobSpec{
+					Template: podSpec,
+				},
+			}
+
+			// Apply annotations
+			cephv1.GetCleanupAnnotations(cluster.Spec.Annotations).ApplyToObjectMeta(&job.ObjectMeta)
+
+			if err := c.runCleanUpJobWithRetry(cluster, hostName, job); err != nil {
+				logger.Errorf("failed to run cluster clean up job on node %q. %v", hostName, err)
+				c.updateCleanupNodeStatus(cluster, hostName, cleanupPhaseFailed, err.Error())
+				continue
+			}
+		}
 
-		if err := k8sutil.RunReplaceableJob(c.context.Clientset, job, true); err != nil {
-			logger.Errorf("failed to run cluster clean up job on node %q. %v", hostName, err)
+		// runCleanUpJobWithRetry only confirms the Job was accepted by the API server, not that
+		// the pod it schedules has actually wiped the node, so wait for the Job to finish before
+		// reporting success.
+		succeeded, err := c.waitForCleanUpJobCompletion(cluster.Namespace, jobName)
+		if err != nil {
+			logger.Errorf("failed to determine completion of clean up job on node %q. %v", hostName, err)
+			c.updateCleanupNodeStatus(cluster, hostName, cleanupPhaseFailed, err.Error())
+			continue
 		}
+		if !succeeded {
+			logger.Errorf("clean up job on node %q did not complete successfully", hostName)
+			c.updateCleanupNodeStatus(cluster, hostName, cleanupPhaseFailed, "cleanup job failed")
+			continue
+		}
+
+		c.updateCleanupNodeStatus(cluster, hostName, cleanupPhaseSucceeded, "")
 	}
 }
 
-func (c *ClusterController) cleanUpJobContainer(cluster *cephv1.CephCluster, monSecret, cephFSID string) v1.Container {
+func (c *ClusterController) cleanUpJobContainer(cluster *cephv1.CephCluster, hostName, monSecret, cephFSID string) v1.Container {
 	volumeMounts := []v1.VolumeMount{}
 	envVars := []v1.EnvVar{}
 	if cluster.Spec.DataDirHostPath != "" {
@@ -104,8 +165,10 @@ func (c *ClusterController) cleanUpJobContainer(cluster *cephv1.CephCluster, mon
 			{Name: monitorSecret, Value: monSecret},
 			{Name: clusterFSID, Value: cephFSID},
 			{Name: "ROOK_LOG_LEVEL", Value: "DEBUG"},
+			{Name: cleanup.EnvHostName, Value: hostName},
 			mon.PodNamespaceEnvVar(cluster.Namespace),
 		}...)
+		envVars = append(envVars, sanitizeDisksEnvVars(cluster.Spec.CleanupPolicy.SanitizeDisks)...)
 	}
 
 	return v1.Container{
@@ -119,7 +182,7 @@ func (c *ClusterController) cleanUpJobContainer(cluster *cephv1.CephCluster, mon
 	}
 }
 
-func (c *ClusterController) cleanUpJobTemplateSpec(cluster *cephv1.CephCluster, monSecret, clusterFSID string) v1.PodTemplateSpec {
+func (c *ClusterController) cleanUpJobTemplateSpec(cluster *cephv1.CephCluster, hostName, monSecret, clusterFSID string) v1.PodTemplateSpec {
 	volumes := []v1.Volume{}
 	hostPathVolume := v1.Volume{Name: volumeName, VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: cluster.Spec.DataDirHostPath}}}
 	devVolume := v1.Volume{Name: "devices", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/dev"}}}
@@ -132,7 +195,7 @@ func (c *ClusterController) cleanUpJobTemplateSpec(cluster *cephv1.CephCluster,
 		},
 		Spec: v1.PodSpec{
 			Containers: []v1.Container{
-				c.cleanUpJobContainer(cluster, monSecret, clusterFSID),
+				c.cleanUpJobContainer(cluster, hostName, monSecret, clusterFSID),
 			},
 			Volumes:           volumes,
 			RestartPolicy:     v1.RestartPolicyOnFailure,
@@ -162,8 +225,9 @@ func (c *ClusterController) waitForCephDaemonCleanUp(stopCleanupCh chan struct{}
 				return nil
 			}
 
-			logger.Debugf("waiting for ceph daemons in cluster %q to be cleaned up. Retrying in %q",
-				cluster.Namespace, retryInterval.String())
+			message := fmt.Sprintf("waiting for ceph daemons on %d node(s) to be cleaned up, retrying in %s", len(cephHosts), retryInterval.String())
+			logger.Debug(message)
+			c.updateCleanupStatusMessage(cluster, message)
 			break
 		case <-stopCleanupCh:
 			return errors.New("cancelling the host cleanup job")
@@ -0,0 +1,246 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+)
+
+const (
+	cleanupPhasePending   = "Pending"
+	cleanupPhaseRunning   = "Running"
+	cleanupPhaseSucceeded = "Succeeded"
+	cleanupPhaseFailed    = "Failed"
+
+	// cleanupFinalizerName blocks CephCluster deletion until host cleanup jobs finish or are
+	// force-cancelled, so an operator restart mid-cleanup doesn't orphan in-flight jobs.
+	cleanupFinalizerName = "cephcluster-cleanup.ceph.rook.io"
+
+	cleanupJobMaxRetries = 3
+	cleanupJobRetryDelay = 10 * time.Second
+
+	cleanupJobPollInterval = 10 * time.Second
+	cleanupJobTimeout      = 30 * time.Minute
+)
+
+// runCleanUpJobWithRetry runs the per-node cleanup job, retrying with a fixed backoff on failure
+// so a transient kubelet/API server hiccup doesn't strand a node in a failed cleanup state.
+func (c *ClusterController) runCleanUpJobWithRetry(cluster *cephv1.CephCluster, hostName string, job *batch.Job) error {
+	var lastErr error
+	for attempt := 1; attempt <= cleanupJobMaxRetries; attempt++ {
+		lastErr = k8sutil.RunReplaceableJob(c.context.Clientset, job, true)
+		if lastErr == nil {
+			return nil
+		}
+
+		logger.Warningf("attempt %d/%d to run cleanup job on node %q failed. %v", attempt, cleanupJobMaxRetries, hostName, lastErr)
+		c.updateCleanupNodeStatus(cluster, hostName, cleanupPhaseRunning, lastErr.Error())
+		if attempt < cleanupJobMaxRetries {
+			time.Sleep(cleanupJobRetryDelay)
+		}
+	}
+
+	return lastErr
+}
+
+// waitForCleanUpJobCompletion polls the named Job until its pod has reported success or failure,
+// so the caller can reflect the node's actual wipe outcome in the cleanup status rather than the
+// mere fact that the Job was accepted by the API server.
+func (c *ClusterController) waitForCleanUpJobCompletion(namespace, jobName string) (bool, error) {
+	for start := time.Now(); time.Since(start) < cleanupJobTimeout; {
+		job, err := c.context.Clientset.BatchV1().Jobs(namespace).Get(jobName, metav1.GetOptions{})
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to get clean up job %q", jobName)
+		}
+
+		if jobComplete(job) {
+			return true, nil
+		}
+		if jobFailed(job) {
+			return false, nil
+		}
+
+		time.Sleep(cleanupJobPollInterval)
+	}
+
+	return false, errors.Errorf("timed out after %s waiting for clean up job %q to complete", cleanupJobTimeout, jobName)
+}
+
+// jobComplete reports whether job has a true JobComplete condition.
+func jobComplete(job *batch.Job) bool {
+	return jobConditionTrue(job, batch.JobComplete)
+}
+
+// jobFailed reports whether job has a true JobFailed condition.
+func jobFailed(job *batch.Job) bool {
+	return jobConditionTrue(job, batch.JobFailed)
+}
+
+func jobConditionTrue(job *batch.Job, condType batch.JobConditionType) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == condType && cond.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// initCleanupStatus seeds the cluster's cleanup status with a Pending entry for each node that
+// doesn't already have one, so `kubectl get cephcluster` shows the full expected scope of work
+// before any job has started. Nodes that already have recorded status (e.g. from a previous
+// startClusterCleanUp run that was interrupted by an operator restart) keep their existing phase
+// rather than being reset to Pending, so a completed node's progress isn't lost on retry.
+func (c *ClusterController) initCleanupStatus(cluster *cephv1.CephCluster, cephHosts []string) {
+	c.updateCleanupStatus(cluster, func(status *cephv1.CleanupStatus) {
+		existing := make(map[string]cephv1.CleanupNodeStatus, len(status.Nodes))
+		for _, node := range status.Nodes {
+			existing[node.Node] = node
+		}
+
+		nodes := make([]cephv1.CleanupNodeStatus, 0, len(cephHosts))
+		for _, hostName := range cephHosts {
+			if node, ok := existing[hostName]; ok {
+				nodes = append(nodes, node)
+				continue
+			}
+			nodes = append(nodes, cephv1.CleanupNodeStatus{Node: hostName, Phase: cleanupPhasePending})
+		}
+
+		status.Nodes = nodes
+		status.Message = "starting cluster clean up"
+	})
+}
+
+// updateCleanupNodeStatus records the phase of a single node's cleanup job.
+func (c *ClusterController) updateCleanupNodeStatus(cluster *cephv1.CephCluster, hostName, phase, message string) {
+	c.updateCleanupStatus(cluster, func(status *cephv1.CleanupStatus) {
+		for i := range status.Nodes {
+			if status.Nodes[i].Node == hostName {
+				status.Nodes[i].Phase = phase
+				status.Nodes[i].Message = message
+				return
+			}
+		}
+		status.Nodes = append(status.Nodes, cephv1.CleanupNodeStatus{Node: hostName, Phase: phase, Message: message})
+	})
+}
+
+// updateCleanupStatusMessage records a cluster-wide progress message, e.g. while still waiting
+// for ceph daemons to terminate before any per-node job has been created.
+func (c *ClusterController) updateCleanupStatusMessage(cluster *cephv1.CephCluster, message string) {
+	c.updateCleanupStatus(cluster, func(status *cephv1.CleanupStatus) {
+		status.Message = message
+	})
+}
+
+// updateCleanupStatus re-fetches the CephCluster, applies mutate to its cleanup status, and
+// persists the result. Streaming progress into the CR status (rather than only logging it) lets
+// users and GitOps tooling watch cleanup progress with `kubectl get`.
+func (c *ClusterController) updateCleanupStatus(cluster *cephv1.CephCluster, mutate func(status *cephv1.CleanupStatus)) {
+	current, err := c.context.RookClientset.CephV1().CephClusters(cluster.Namespace).Get(cluster.Name, metav1.GetOptions{})
+	if err != nil {
+		logger.Warningf("failed to get cluster %q to update cleanup status. %v", cluster.Name, err)
+		return
+	}
+	if current.Status == nil {
+		current.Status = &cephv1.ClusterStatus{}
+	}
+	if current.Status.CleanupStatus == nil {
+		current.Status.CleanupStatus = &cephv1.CleanupStatus{}
+	}
+
+	mutate(current.Status.CleanupStatus)
+
+	if _, err := c.context.RookClientset.CephV1().CephClusters(cluster.Namespace).UpdateStatus(current); err != nil {
+		logger.Warningf("failed to persist cleanup status for cluster %q. %v", cluster.Name, err)
+	}
+}
+
+// addCleanupFinalizer ensures the CephCluster isn't deleted until startClusterCleanUp releases
+// the finalizer via releaseCleanupFinalizerIfDone, either because every node's cleanup succeeded
+// or the operator set ForceDelete to bypass a stuck or failed cleanup.
+func (c *ClusterController) addCleanupFinalizer(cluster *cephv1.CephCluster) error {
+	current, err := c.context.RookClientset.CephV1().CephClusters(cluster.Namespace).Get(cluster.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if k8sutil.Contains(current.Finalizers, cleanupFinalizerName) {
+		return nil
+	}
+
+	current.Finalizers = append(current.Finalizers, cleanupFinalizerName)
+	_, err = c.context.RookClientset.CephV1().CephClusters(cluster.Namespace).Update(current)
+	return err
+}
+
+// removeCleanupFinalizer unblocks CephCluster deletion once host cleanup is done.
+func (c *ClusterController) removeCleanupFinalizer(cluster *cephv1.CephCluster) error {
+	current, err := c.context.RookClientset.CephV1().CephClusters(cluster.Namespace).Get(cluster.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	current.Finalizers = k8sutil.Remove(current.Finalizers, cleanupFinalizerName)
+	_, err = c.context.RookClientset.CephV1().CephClusters(cluster.Namespace).Update(current)
+	return err
+}
+
+// releaseCleanupFinalizerIfDone removes the cleanup finalizer once every node has finished
+// cleanup successfully, or immediately if the operator set ForceDelete to bypass a stuck or
+// failed cleanup. Otherwise the finalizer is left in place so the next reconcile can retry the
+// nodes that failed, rather than deleting the CephCluster with disks left unwiped.
+func (c *ClusterController) releaseCleanupFinalizerIfDone(cluster *cephv1.CephCluster) {
+	if cluster.Spec.CleanupPolicy.ForceDelete {
+		logger.Infof("cluster %q cleanup policy has ForceDelete set, releasing the cleanup finalizer regardless of cleanup status", cluster.Name)
+	} else if !c.allNodesCleanedUp(cluster) {
+		logger.Errorf("cluster %q clean up did not succeed on every node, leaving the cleanup finalizer in place for a retry", cluster.Name)
+		return
+	}
+
+	if err := c.removeCleanupFinalizer(cluster); err != nil {
+		logger.Errorf("failed to remove cleanup finalizer from cluster %q. %v", cluster.Name, err)
+	}
+}
+
+// allNodesCleanedUp re-fetches the cluster's latest cleanup status and reports whether every
+// node that was part of this clean up run reached the Succeeded phase.
+func (c *ClusterController) allNodesCleanedUp(cluster *cephv1.CephCluster) bool {
+	current, err := c.context.RookClientset.CephV1().CephClusters(cluster.Namespace).Get(cluster.Name, metav1.GetOptions{})
+	if err != nil {
+		logger.Warningf("failed to get cluster %q to check cleanup status. %v", cluster.Name, err)
+		return false
+	}
+	if current.Status == nil || current.Status.CleanupStatus == nil {
+		// Cleanup was never initialized for this cluster, so there's nothing to confirm yet.
+		return false
+	}
+
+	for _, node := range current.Status.CleanupStatus.Nodes {
+		if node.Phase != cleanupPhaseSucceeded {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/daemon/ceph/cleanup"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// sanitizeMethod, sanitizeDataSource, sanitizeIteration, and sanitizeDryRun name the environment
+// variables the cleanup job container reads to pick a data-destruction method per device.
+// Delegates to pkg/daemon/ceph/cleanup, which the cleanup job container also links against, so
+// the writer and reader can never disagree on the variable names.
+var (
+	sanitizeMethod     = cleanup.EnvMethod
+	sanitizeDataSource = cleanup.EnvDataSource
+	sanitizeIteration  = cleanup.EnvIteration
+	sanitizeDryRun     = cleanup.EnvDryRun
+)
+
+// sanitizeReportConfigMapName returns the name of the ConfigMap a cleanup job on hostName writes
+// its per-device sanitization results to. Delegates to pkg/daemon/ceph/cleanup, which the cleanup
+// job container also links against, so the writer and reader can never disagree on the name.
+func sanitizeReportConfigMapName(hostName string) string {
+	return cleanup.ReportConfigMapName(hostName)
+}
+
+// DeviceSanitizeResult is the outcome of sanitizing a single device on a single node, as reported
+// by the cleanup job container back to the operator.
+type DeviceSanitizeResult = cleanup.DeviceResult
+
+// nodeSanitizeReport is the per-node sanitize report a cleanup job persists to its ConfigMap.
+type nodeSanitizeReport = cleanup.NodeReport
+
+// sanitizeDisksEnvVars translates a CleanupPolicySpec.SanitizeDisks spec into the environment
+// variables the cleanup job container reads to pick a data-destruction method per device. An
+// empty Method leaves the job to use its default quick wipe; DryRun is passed through either way.
+func sanitizeDisksEnvVars(sanitize cephv1.SanitizeDisksSpec) []v1.EnvVar {
+	envVars := []v1.EnvVar{}
+	if sanitize.DryRun {
+		envVars = append(envVars, v1.EnvVar{Name: sanitizeDryRun, Value: "true"})
+	}
+	if sanitize.Method == "" {
+		return envVars
+	}
+
+	envVars = append(envVars,
+		v1.EnvVar{Name: sanitizeMethod, Value: string(sanitize.Method)},
+		v1.EnvVar{Name: sanitizeDataSource, Value: string(sanitize.DataSource)},
+		v1.EnvVar{Name: sanitizeIteration, Value: fmt.Sprintf("%d", sanitize.Iteration)},
+	)
+
+	return envVars
+}
+
+// aggregateSanitizeReport reads the per-node sanitize report ConfigMaps left behind by the
+// cleanup jobs on cephHosts and combines them into a single cluster-wide report. This is called
+// before the CephCluster finalizer is removed so operators have one place to confirm every disk
+// was wiped with the expected method before the cluster object disappears.
+func (c *ClusterController) aggregateSanitizeReport(namespace string, cephHosts []string) ([]nodeSanitizeReport, error) {
+	report := make([]nodeSanitizeReport, 0, len(cephHosts))
+
+	for _, hostName := range cephHosts {
+		cmName := sanitizeReportConfigMapName(hostName)
+		cm, err := c.context.Clientset.CoreV1().ConfigMaps(namespace).Get(cmName, metav1.GetOptions{})
+		if err != nil {
+			logger.Warningf("no sanitize report found for node %q, skipping. %v", hostName, err)
+			continue
+		}
+
+		var nodeReport nodeSanitizeReport
+		if err := json.Unmarshal([]byte(cm.Data["report"]), &nodeReport); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse sanitize report for node %q", hostName)
+		}
+		report = append(report, nodeReport)
+	}
+
+	for _, nodeReport := range report {
+		for _, result := range nodeReport.Results {
+			if !result.Success {
+				logger.Errorf("failed to sanitize device %q on node %q using method %q. %s", result.Device, nodeReport.Node, result.Method, result.Error)
+				continue
+			}
+			logger.Infof("sanitized device %q on node %q using method %q (%d bytes, %ds, dryRun=%t)",
+				result.Device, nodeReport.Node, result.Method, result.BytesWiped, result.DurationSec, nodeReport.DryRun)
+		}
+	}
+
+	return report, nil
+}
@@ -181,6 +181,13 @@ func (r *ReconcileObjectZone) reconcile(request reconcile.Request) (reconcile.Re
 		return reconcileResponse, err
 	}
 
+	// If this zone bootstraps from a remote primary, pull its realm/period before assuming any
+	// local zone group or zone already exists
+	reconcileResponse, err = r.reconcilePullRealm(cephObjectZone, realmName)
+	if err != nil {
+		return r.setFailedStatus(request.NamespacedName, "failed to pull realm", err)
+	}
+
 	// Make sure zone group has been created in Ceph Cluster
 	reconcileResponse, err = r.reconcileCephZoneGroup(cephObjectZone, realmName)
 	if err != nil {
@@ -193,6 +200,22 @@ func (r *ReconcileObjectZone) reconcile(request reconcile.Request) (reconcile.Re
 		return r.setFailedStatus(request.NamespacedName, "failed to create ceph zone", err)
 	}
 
+	// Discover RGW service endpoints for this zone and commit a realm period if they changed
+	reconcileResponse, err = r.reconcileEndpoints(cephObjectZone, realmName)
+	if err != nil {
+		return r.setFailedStatus(request.NamespacedName, "failed to reconcile zone endpoints", err)
+	}
+
+	// Reconcile multi-site sync policy (sync groups/flows/pipes) for this zone, if configured
+	isMasterZone, err := r.isMasterZone(cephObjectZone, realmName)
+	if err != nil {
+		return r.setFailedStatus(request.NamespacedName, "failed to determine master zone", err)
+	}
+	reconcileResponse, err = r.reconcileSyncPolicy(cephObjectZone, realmName, isMasterZone)
+	if err != nil {
+		return r.setFailedStatus(request.NamespacedName, "failed to reconcile sync policy", err)
+	}
+
 	// Set Ready status, we are done reconciling
 	updateStatus(r.client, request.NamespacedName, k8sutil.ReadyStatus)
 
@@ -248,6 +271,25 @@ func (r *ReconcileObjectZone) createCephZone(zone *cephv1.CephObjectZone, realmN
 	return reconcile.Result{}, nil
 }
 
+// isMasterZone reports whether the given zone is currently the master zone of its zonegroup.
+func (r *ReconcileObjectZone) isMasterZone(zone *cephv1.CephObjectZone, realmName string) (bool, error) {
+	realmArg := fmt.Sprintf("--rgw-realm=%s", realmName)
+	zoneGroupArg := fmt.Sprintf("--rgw-zonegroup=%s", zone.Spec.ZoneGroup)
+	objContext := object.NewContext(r.context, r.clusterInfo, zone.Name)
+
+	output, err := object.RunAdminCommandNoRealm(objContext, "zonegroup", "get", realmArg, zoneGroupArg)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get zone group %q", zone.Spec.ZoneGroup)
+	}
+
+	masterZone, err := decodeMasterZone(output)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to parse `radosgw-admin zonegroup get` output")
+	}
+
+	return masterZone == zone.Name, nil
+}
+
 func (r *ReconcileObjectZone) reconcileObjectZoneGroup(zone *cephv1.CephObjectZone) (string, reconcile.Result, error) {
 	// Verify the object zone API object actually exists
 	zoneGroup, err := r.context.RookClientset.CephV1().CephObjectZoneGroups(zone.Namespace).Get(zone.Spec.ZoneGroup, metav1.GetOptions{})
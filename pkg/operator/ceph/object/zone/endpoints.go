@@ -0,0 +1,307 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package objectzone to manage a rook object zone.
+package zone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/object"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// periodLockConfigMapFmt names the ConfigMap used as a cheap lease to serialize
+	// `period update --commit` calls across every CephObjectZone reconcile racing on the same realm.
+	periodLockConfigMapFmt = "rook-ceph-realm-%s-period-lock"
+	periodLockHolderKey    = "holder"
+	periodLockTimeKey      = "acquiredAt"
+	periodLockTTL          = 30 * time.Second
+
+	// podNamespaceEnvVar is the operator pod's own namespace, set via the downward API. It's used
+	// as the period commit lease's namespace so that zones belonging to the same realm but
+	// reconciled from CRs in different namespaces contend for the same lease ConfigMap.
+	podNamespaceEnvVar = "POD_NAMESPACE"
+)
+
+// periodInfo is the subset of `radosgw-admin period get` this reconciler cares about.
+type periodInfo struct {
+	ID    string `json:"id"`
+	Epoch int    `json:"epoch"`
+}
+
+// reconcileEndpoints discovers the RGW service endpoints that back this zone, pushes any change
+// to the Ceph zone via `zone modify --endpoints`, and commits a realm period at most once per
+// realm change using a ConfigMap lease to keep concurrent zone reconciles from racing.
+func (r *ReconcileObjectZone) reconcileEndpoints(zone *cephv1.CephObjectZone, realmName string) (reconcile.Result, error) {
+	endpoints, err := r.discoverZoneEndpoints(zone)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to discover zone endpoints")
+	}
+
+	realmArg := fmt.Sprintf("--rgw-realm=%s", realmName)
+	zoneGroupArg := fmt.Sprintf("--rgw-zonegroup=%s", zone.Spec.ZoneGroup)
+	zoneArg := fmt.Sprintf("--rgw-zone=%s", zone.Name)
+	objContext := object.NewContext(r.context, r.clusterInfo, zone.Name)
+
+	var currentEndpoints []string
+	if zone.Status != nil {
+		currentEndpoints = zone.Status.Endpoints
+	}
+
+	if !endpointsEqual(currentEndpoints, endpoints) {
+		logger.Infof("endpoints for zone %q changed from %v to %v, updating zone and committing period", zone.Name, currentEndpoints, endpoints)
+
+		endpointsArg := fmt.Sprintf("--endpoints=%s", strings.Join(endpoints, ","))
+		if _, err := object.RunAdminCommandNoRealm(objContext, "zone", "modify", realmArg, zoneGroupArg, zoneArg, endpointsArg); err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to set endpoints on zone %q", zone.Name)
+		}
+
+		if err := r.commitPeriodWithLease(objContext, zone, realmName); err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to commit period after endpoint update")
+		}
+	}
+
+	period, err := getPeriodInfo(objContext)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to read period info")
+	}
+
+	r.updateEndpointStatus(zone, endpoints, period)
+	return reconcile.Result{}, nil
+}
+
+// discoverZoneEndpoints finds the CephObjectStores backed by this zone, in any namespace of this
+// cluster, and returns the stable in-cluster endpoint of each one's RGW service. Listing cluster-
+// wide requires the operator's ClusterRole to grant list/watch on cephobjectstores across all
+// namespaces, not just its own; this checkout doesn't carry the RBAC manifests to update, so that
+// needs to happen alongside this change when it lands in the full deploy tree. Discovering stores
+// in a different Kubernetes cluster (e.g. via a pull secret) is not handled here either; that
+// requires reaching a remote API server and is out of scope for this in-cluster lookup.
+func (r *ReconcileObjectZone) discoverZoneEndpoints(zone *cephv1.CephObjectZone) ([]string, error) {
+	stores, err := r.context.RookClientset.CephV1().CephObjectStores(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list CephObjectStores")
+	}
+
+	endpoints := []string{}
+	for _, store := range stores.Items {
+		if !storeBacksZone(store, zone) {
+			continue
+		}
+
+		svc, err := r.context.Clientset.CoreV1().Services(store.Namespace).Get(object.AppName+"-"+store.Name, metav1.GetOptions{})
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				logger.Debugf("service for CephObjectStore %q not found yet, skipping", store.Name)
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get service for CephObjectStore %q", store.Name)
+		}
+
+		port := servicePort(svc)
+		if port == 0 {
+			continue
+		}
+		scheme := "http"
+		if store.Spec.Gateway.SecurePort != 0 && port == int32(store.Spec.Gateway.SecurePort) {
+			scheme = "https"
+		}
+		endpoints = append(endpoints, fmt.Sprintf("%s://%s.%s.svc:%d", scheme, svc.Name, svc.Namespace, port))
+	}
+
+	sort.Strings(endpoints)
+	return endpoints, nil
+}
+
+// storeBacksZone reports whether store references zone, resolving store's zone namespace to its
+// own namespace when Spec.Zone.Namespace is left empty.
+func storeBacksZone(store cephv1.CephObjectStore, zone *cephv1.CephObjectZone) bool {
+	zoneNamespace := store.Spec.Zone.Namespace
+	if zoneNamespace == "" {
+		zoneNamespace = store.Namespace
+	}
+	return store.Spec.Zone.Name == zone.Name && zoneNamespace == zone.Namespace
+}
+
+func servicePort(svc *v1.Service) int32 {
+	for _, p := range svc.Spec.Ports {
+		return p.Port
+	}
+	return 0
+}
+
+func endpointsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// commitPeriodWithLease holds a short ConfigMap-backed lease before running `period update
+// --commit` so that multiple CephObjectZone reconciles triggered by the same realm change don't
+// each try to commit their own period, which `radosgw-admin` does not handle gracefully. The
+// lease lives in the operator's own namespace rather than the zone's, since a realm's zones can
+// be spread across several namespaces and must all contend for the same lease to actually
+// serialize against one another.
+func (r *ReconcileObjectZone) commitPeriodWithLease(objContext *object.Context, zone *cephv1.CephObjectZone, realmName string) error {
+	cmName := fmt.Sprintf(periodLockConfigMapFmt, realmName)
+	holder := zone.Namespace + "/" + zone.Name
+	leaseNamespace := periodLockNamespace(zone.Namespace)
+
+	if err := r.acquirePeriodLock(leaseNamespace, cmName, holder); err != nil {
+		return errors.Wrap(err, "failed to acquire period commit lease")
+	}
+	defer r.releasePeriodLock(leaseNamespace, cmName, holder)
+
+	_, err := object.RunAdminCommandNoRealm(objContext, "period", "update", "--commit")
+	return err
+}
+
+// periodLockNamespace returns the operator's own namespace, read from the POD_NAMESPACE
+// downward-API env var, so every zone reconcile for a given realm shares one lease regardless of
+// which namespace each zone's CR lives in. Falls back to fallback (the calling zone's own
+// namespace) if POD_NAMESPACE isn't set, which only serializes zones within that one namespace.
+func periodLockNamespace(fallback string) string {
+	if ns := os.Getenv(podNamespaceEnvVar); ns != "" {
+		return ns
+	}
+	logger.Warningf("%s is not set, falling back to %q for the period commit lease; zones for the same realm in other namespaces will not be serialized against this one", podNamespaceEnvVar, fallback)
+	return fallback
+}
+
+func (r *ReconcileObjectZone) acquirePeriodLock(namespace, cmName, holder string) error {
+	for {
+		cm, err := r.context.Clientset.CoreV1().ConfigMaps(namespace).Get(cmName, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			cm = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: cmName, Namespace: namespace},
+				Data: map[string]string{
+					periodLockHolderKey: holder,
+					periodLockTimeKey:   time.Now().UTC().Format(time.RFC3339),
+				},
+			}
+			if _, err := r.context.Clientset.CoreV1().ConfigMaps(namespace).Create(cm); err == nil {
+				return nil
+			} else if !kerrors.IsAlreadyExists(err) {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if cm.Data[periodLockHolderKey] == holder {
+			return nil
+		}
+
+		acquiredAt, err := time.Parse(time.RFC3339, cm.Data[periodLockTimeKey])
+		if err == nil && time.Since(acquiredAt) < periodLockTTL {
+			time.Sleep(time.Second)
+			continue
+		}
+
+		// lease expired or unparsable, steal it
+		cm.Data[periodLockHolderKey] = holder
+		cm.Data[periodLockTimeKey] = time.Now().UTC().Format(time.RFC3339)
+		if _, err := r.context.Clientset.CoreV1().ConfigMaps(namespace).Update(cm); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+// releasePeriodLock deletes the lease ConfigMap only if holder still owns it. If the lease was
+// stolen out from under holder after its TTL expired (e.g. holder's commit ran long), some other
+// reconciler now owns it, and an unconditional delete here would tear down that reconciler's
+// active lease instead of holder's own.
+func (r *ReconcileObjectZone) releasePeriodLock(namespace, cmName, holder string) {
+	cm, err := r.context.Clientset.CoreV1().ConfigMaps(namespace).Get(cmName, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			logger.Warningf("failed to check ownership of period commit lease %q before releasing it. %v", cmName, err)
+		}
+		return
+	}
+	if cm.Data[periodLockHolderKey] != holder {
+		logger.Infof("period commit lease %q was stolen from %q, leaving it in place for its new holder", cmName, holder)
+		return
+	}
+
+	// Guard the delete with the ResourceVersion we just read so that if another reconciler steals
+	// the lease between our Get above and this Delete, the API server rejects the delete as a
+	// conflict instead of removing the new holder's lease out from under it.
+	preconditions := &metav1.Preconditions{ResourceVersion: &cm.ResourceVersion}
+	err = r.context.Clientset.CoreV1().ConfigMaps(namespace).Delete(cmName, &metav1.DeleteOptions{Preconditions: preconditions})
+	if err != nil && !kerrors.IsNotFound(err) && !kerrors.IsConflict(err) {
+		logger.Warningf("failed to release period commit lease %q held by %q. %v", cmName, holder, err)
+	}
+}
+
+func getPeriodInfo(objContext *object.Context) (*periodInfo, error) {
+	output, err := object.RunAdminCommandNoRealm(objContext, "period", "get")
+	if err != nil {
+		return nil, err
+	}
+
+	period := &periodInfo{}
+	if err := json.Unmarshal([]byte(output), period); err != nil {
+		return nil, errors.Wrap(err, "failed to parse `radosgw-admin period get` output")
+	}
+	return period, nil
+}
+
+// updateEndpointStatus records the zone's current endpoints and the realm's period id/epoch so
+// users can verify propagation across a multi-site topology from the CR alone.
+func (r *ReconcileObjectZone) updateEndpointStatus(zone *cephv1.CephObjectZone, endpoints []string, period *periodInfo) {
+	name := types.NamespacedName{Namespace: zone.Namespace, Name: zone.Name}
+	current := &cephv1.CephObjectZone{}
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		logger.Warningf("failed to retrieve object zone %q to update endpoint status. %v", name, err)
+		return
+	}
+	if current.Status == nil {
+		current.Status = &cephv1.Status{}
+	}
+
+	current.Status.Endpoints = endpoints
+	current.Status.PeriodID = period.ID
+	current.Status.PeriodEpoch = period.Epoch
+
+	if err := opcontroller.UpdateStatus(r.client, current); err != nil {
+		logger.Errorf("failed to set object zone %q endpoint status. %v", name, err)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zone
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointsEqual(t *testing.T) {
+	assert.True(t, endpointsEqual(nil, nil))
+	assert.True(t, endpointsEqual(nil, []string{}))
+	assert.True(t, endpointsEqual([]string{"http://a:80"}, []string{"http://a:80"}))
+	assert.False(t, endpointsEqual([]string{"http://a:80"}, nil))
+	assert.False(t, endpointsEqual([]string{"http://a:80"}, []string{"http://b:80"}))
+	assert.False(t, endpointsEqual([]string{"http://a:80"}, []string{"http://a:80", "http://b:80"}))
+}
+
+func TestStoreBacksZone(t *testing.T) {
+	zone := &cephv1.CephObjectZone{ObjectMeta: metav1.ObjectMeta{Name: "zone-a", Namespace: "zone-ns"}}
+
+	sameNamespaceNoOverride := cephv1.CephObjectStore{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "zone-ns"},
+		Spec:       cephv1.ObjectStoreSpec{Zone: cephv1.ZoneSpec{Name: "zone-a"}},
+	}
+	assert.True(t, storeBacksZone(sameNamespaceNoOverride, zone))
+
+	crossNamespaceOverride := cephv1.CephObjectStore{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "store-ns"},
+		Spec:       cephv1.ObjectStoreSpec{Zone: cephv1.ZoneSpec{Name: "zone-a", Namespace: "zone-ns"}},
+	}
+	assert.True(t, storeBacksZone(crossNamespaceOverride, zone))
+
+	differentZoneName := cephv1.CephObjectStore{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "zone-ns"},
+		Spec:       cephv1.ObjectStoreSpec{Zone: cephv1.ZoneSpec{Name: "zone-b"}},
+	}
+	assert.False(t, storeBacksZone(differentZoneName, zone))
+
+	noNamespaceOverrideWrongNamespace := cephv1.CephObjectStore{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "store-ns"},
+		Spec:       cephv1.ObjectStoreSpec{Zone: cephv1.ZoneSpec{Name: "zone-a"}},
+	}
+	assert.False(t, storeBacksZone(noNamespaceOverrideWrongNamespace, zone))
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package objectzone to manage a rook object zone.
+package zone
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/object"
+	"github.com/rook/rook/pkg/util/exec"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcilePullRealm bootstraps a secondary cluster from a remote primary's realm. When
+// zone.Spec.PullRealm is set, it pulls the realm and period from the remote endpoint using the
+// referenced system-user credentials instead of requiring a matching CephObjectRealm CR to
+// already exist locally, then lets the normal zone creation path run against the pulled realm.
+func (r *ReconcileObjectZone) reconcilePullRealm(zone *cephv1.CephObjectZone, realmName string) (reconcile.Result, error) {
+	pullSpec := zone.Spec.PullRealm
+	if pullSpec == nil {
+		return reconcile.Result{}, nil
+	}
+
+	accessKey, secretKey, err := r.readPullRealmCredentials(zone.Namespace, pullSpec)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to read pull realm credentials")
+	}
+
+	objContext := object.NewContext(r.context, r.clusterInfo, zone.Name)
+
+	localEpoch, err := localRealmEpoch(objContext, realmName)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to read local realm epoch")
+	}
+
+	remoteEpoch, err := r.remoteRealmEpoch(objContext, pullSpec.Endpoint, accessKey, secretKey)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to read remote realm epoch")
+	}
+
+	if localEpoch >= remoteEpoch && localEpoch != 0 {
+		logger.Debugf("realm %q is already at epoch %d, nothing to pull", realmName, localEpoch)
+		return reconcile.Result{}, nil
+	}
+
+	logger.Infof("pulling realm %q from %q (local epoch %d, remote epoch %d)", realmName, pullSpec.Endpoint, localEpoch, remoteEpoch)
+
+	realmArgs := []string{"realm", "pull",
+		fmt.Sprintf("--url=%s", pullSpec.Endpoint),
+		fmt.Sprintf("--access-key=%s", accessKey),
+		fmt.Sprintf("--secret=%s", secretKey),
+	}
+	if _, err := r.runAdminCommandRedacted(objContext, realmArgs, accessKey, secretKey); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to pull realm")
+	}
+
+	periodArgs := []string{"period", "pull",
+		fmt.Sprintf("--url=%s", pullSpec.Endpoint),
+		fmt.Sprintf("--access-key=%s", accessKey),
+		fmt.Sprintf("--secret=%s", secretKey),
+	}
+	if _, err := r.runAdminCommandRedacted(objContext, periodArgs, accessKey, secretKey); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to pull period")
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// readPullRealmCredentials reads the access and secret keys referenced by zone.Spec.PullRealm.
+// The returned values must never be logged.
+func (r *ReconcileObjectZone) readPullRealmCredentials(namespace string, pullSpec *cephv1.PullRealmSpec) (string, string, error) {
+	accessKey, err := r.readSecretKey(namespace, pullSpec.AccessKeySecretRef)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to read access key secret")
+	}
+
+	secretKey, err := r.readSecretKey(namespace, pullSpec.SecretKeySecretRef)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to read secret key secret")
+	}
+
+	return accessKey, secretKey, nil
+}
+
+func (r *ReconcileObjectZone) readSecretKey(namespace string, ref cephv1.SecretKeySelector) (string, error) {
+	secret, err := r.context.Clientset.CoreV1().Secrets(namespace).Get(ref.Name, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return "", errors.Errorf("secret %q not found", ref.Name)
+		}
+		return "", err
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", errors.Errorf("key %q not found in secret %q", ref.Key, ref.Name)
+	}
+
+	return strings.TrimSpace(string(value)), nil
+}
+
+// runAdminCommandRedacted runs a radosgw-admin command, logging the invocation with any values in
+// redact replaced so system-user credentials never reach the operator log.
+func (r *ReconcileObjectZone) runAdminCommandRedacted(objContext *object.Context, args []string, redact ...string) (string, error) {
+	logger.Infof("running radosgw-admin %s", redactArgs(args, redact))
+	return object.RunAdminCommandNoRealm(objContext, args...)
+}
+
+func redactArgs(args []string, redact []string) string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i, arg := range redacted {
+		for _, secret := range redact {
+			if secret != "" && strings.Contains(arg, secret) {
+				redacted[i] = strings.Replace(arg, secret, "***", 1)
+			}
+		}
+	}
+	return strings.Join(redacted, " ")
+}
+
+func localRealmEpoch(objContext *object.Context, realmName string) (int, error) {
+	output, err := object.RunAdminCommandNoRealm(objContext, "realm", "get", fmt.Sprintf("--rgw-realm=%s", realmName))
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			// realm doesn't exist locally yet
+			return 0, nil
+		}
+		return 0, errors.Wrap(err, "radosgw-admin realm get failed")
+	}
+
+	period, err := decodeRealmEpoch(output)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse `radosgw-admin realm get` output")
+	}
+	return period, nil
+}
+
+func (r *ReconcileObjectZone) remoteRealmEpoch(objContext *object.Context, endpoint, accessKey, secretKey string) (int, error) {
+	args := []string{"realm", "get",
+		fmt.Sprintf("--url=%s", endpoint),
+		fmt.Sprintf("--access-key=%s", accessKey),
+		fmt.Sprintf("--secret=%s", secretKey),
+	}
+	output, err := r.runAdminCommandRedacted(objContext, args, accessKey, secretKey)
+	if err != nil {
+		return 0, err
+	}
+
+	return decodeRealmEpoch(output)
+}
+
+func decodeRealmEpoch(output string) (int, error) {
+	var realm struct {
+		Epoch int `json:"epoch"`
+	}
+	if err := json.Unmarshal([]byte(output), &realm); err != nil {
+		return 0, err
+	}
+	return realm.Epoch, nil
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactArgs(t *testing.T) {
+	args := []string{"realm", "get", "--url=http://remote", "--access-key=abc", "--secret=def"}
+
+	redacted := redactArgs(args, []string{"abc", "def"})
+
+	assert.Equal(t, "realm get --url=http://remote --access-key=*** --secret=***", redacted)
+}
+
+func TestRedactArgsIgnoresEmptySecrets(t *testing.T) {
+	args := []string{"period", "pull", "--url=http://remote"}
+
+	redacted := redactArgs(args, []string{"", ""})
+
+	assert.Equal(t, "period pull --url=http://remote", redacted)
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package objectzone to manage a rook object zone.
+package zone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"syscall"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/object"
+	"github.com/rook/rook/pkg/util/exec"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	syncPolicyStatusEnabled   = "enabled"
+	syncPolicyStatusAllowed   = "allowed"
+	syncPolicyStatusForbidden = "forbidden"
+)
+
+// existingSyncGroup is the subset of `radosgw-admin sync group get` output this reconciler cares about.
+type existingSyncGroup struct {
+	ID     string             `json:"id"`
+	Status string             `json:"status"`
+	Flows  []existingSyncFlow `json:"flows"`
+	Pipes  []existingSyncPipe `json:"pipes"`
+}
+
+type existingSyncFlow struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Source string `json:"src_zone,omitempty"`
+	Dest   string `json:"dest_zone,omitempty"`
+}
+
+type existingSyncPipe struct {
+	ID     string              `json:"id"`
+	Source existingSyncPipeEnd `json:"source"`
+	Dest   existingSyncPipeEnd `json:"destination"`
+}
+
+type existingSyncPipeEnd struct {
+	Zone   string `json:"zone"`
+	Bucket string `json:"bucket,omitempty"`
+}
+
+// reconcileSyncPolicy converges the multi-site sync groups/flows/pipes configured on the Ceph
+// cluster with the desired state in zone.Spec.SyncPolicy. A zone must be explicitly marked as
+// allowed to run policy changes if it is the master zone of its zonegroup, since a bad policy
+// there can break replication for every zone in the group.
+func (r *ReconcileObjectZone) reconcileSyncPolicy(zone *cephv1.CephObjectZone, realmName string, isMasterZone bool) (reconcile.Result, error) {
+	if zone.Spec.SyncPolicy == nil {
+		return reconcile.Result{}, nil
+	}
+
+	if isMasterZone && !zone.Spec.SyncPolicy.AllowMaster {
+		logger.Warningf("zone %q is the master zone of zonegroup %q, refusing to apply sync policy without spec.syncPolicy.allowMaster", zone.Name, zone.Spec.ZoneGroup)
+		r.updateSyncPolicyStatus(zone, nil, syncPolicyStatusForbidden)
+		return reconcile.Result{}, nil
+	}
+
+	realmArg := fmt.Sprintf("--rgw-realm=%s", realmName)
+	zoneGroupArg := fmt.Sprintf("--rgw-zonegroup=%s", zone.Spec.ZoneGroup)
+	objContext := object.NewContext(r.context, r.clusterInfo, zone.Name)
+
+	existing, err := getExistingSyncGroups(objContext, realmArg, zoneGroupArg)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to get existing sync policy groups")
+	}
+
+	desired := zone.Spec.SyncPolicy.Groups
+	changed := false
+
+	for _, group := range desired {
+		groupChanged, err := r.reconcileSyncGroup(objContext, realmArg, zoneGroupArg, group, existing)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to reconcile sync group %q", group.ID)
+		}
+		changed = changed || groupChanged
+	}
+
+	for _, stale := range staleSyncGroups(existing, desired) {
+		logger.Infof("removing stale sync group %q from zone %q", stale, zone.Name)
+		if _, err := object.RunAdminCommandNoRealm(objContext, "sync", "group", "remove", realmArg, zoneGroupArg, fmt.Sprintf("--group-id=%s", stale)); err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to remove stale sync group %q", stale)
+		}
+		changed = true
+	}
+
+	if changed {
+		// Route through the shared lease so a concurrent reconcile of a sibling zone (e.g. from
+		// reconcileEndpoints) can't commit its own period for the same realm change at the same time.
+		if err := r.commitPeriodWithLease(objContext, zone, realmName); err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to commit period after updating sync policy")
+		}
+	}
+
+	r.updateSyncPolicyStatus(zone, desired, syncPolicyStatusEnabled)
+	return reconcile.Result{}, nil
+}
+
+// reconcileSyncGroup creates or modifies a single sync group along with its flows and pipes.
+// It returns true if any `radosgw-admin` command changed cluster state.
+func (r *ReconcileObjectZone) reconcileSyncGroup(objContext *object.Context, realmArg, zoneGroupArg string, group cephv1.SyncPolicyGroupSpec, existing []existingSyncGroup) (bool, error) {
+	groupIDArg := fmt.Sprintf("--group-id=%s", group.ID)
+	statusArg := fmt.Sprintf("--status=%s", group.Status)
+	changed := false
+
+	if found := findSyncGroup(existing, group.ID); found == nil {
+		logger.Infof("creating sync group %q", group.ID)
+		if _, err := object.RunAdminCommandNoRealm(objContext, "sync", "group", "create", realmArg, zoneGroupArg, groupIDArg, statusArg); err != nil {
+			return false, errors.Wrapf(err, "failed to create sync group %q", group.ID)
+		}
+		changed = true
+	} else if found.Status != group.Status {
+		logger.Infof("updating status of sync group %q to %q", group.ID, group.Status)
+		if _, err := object.RunAdminCommandNoRealm(objContext, "sync", "group", "modify", realmArg, zoneGroupArg, groupIDArg, statusArg); err != nil {
+			return false, errors.Wrapf(err, "failed to modify sync group %q", group.ID)
+		}
+		changed = true
+	}
+
+	var existingFlows []existingSyncFlow
+	var existingPipes []existingSyncPipe
+	if found := findSyncGroup(existing, group.ID); found != nil {
+		existingFlows = found.Flows
+		existingPipes = found.Pipes
+	}
+
+	for _, flow := range group.Flows {
+		if flowUpToDate(existingFlows, flow) {
+			continue
+		}
+		args := []string{realmArg, zoneGroupArg, groupIDArg,
+			fmt.Sprintf("--flow-id=%s", flow.ID),
+			fmt.Sprintf("--flow-type=%s", flow.Type),
+			fmt.Sprintf("--source-zone=%s", flow.SourceZone),
+			fmt.Sprintf("--dest-zone=%s", flow.DestZone),
+		}
+		if _, err := object.RunAdminCommandNoRealm(objContext, append([]string{"sync", "group", "flow", "create"}, args...)...); err != nil {
+			return false, errors.Wrapf(err, "failed to create sync flow %q in group %q", flow.ID, group.ID)
+		}
+		changed = true
+	}
+
+	for _, pipe := range group.Pipes {
+		if pipeUpToDate(existingPipes, pipe) {
+			continue
+		}
+		args := []string{realmArg, zoneGroupArg, groupIDArg,
+			fmt.Sprintf("--pipe-id=%s", pipe.ID),
+			fmt.Sprintf("--source-zones=%s", pipe.Source.Zones),
+			fmt.Sprintf("--dest-zones=%s", pipe.Dest.Zones),
+		}
+		if pipe.Source.Bucket != "" {
+			args = append(args, fmt.Sprintf("--source-bucket=%s", pipe.Source.Bucket))
+		}
+		if pipe.Dest.Bucket != "" {
+			args = append(args, fmt.Sprintf("--dest-bucket=%s", pipe.Dest.Bucket))
+		}
+		if pipe.Filter != nil {
+			if pipe.Filter.Prefix != "" {
+				args = append(args, fmt.Sprintf("--prefix=%s", pipe.Filter.Prefix))
+			}
+			if pipe.Filter.Tag != "" {
+				args = append(args, fmt.Sprintf("--tag=%s", pipe.Filter.Tag))
+			}
+		}
+		if _, err := object.RunAdminCommandNoRealm(objContext, append([]string{"sync", "group", "pipe", "create"}, args...)...); err != nil {
+			return false, errors.Wrapf(err, "failed to create sync pipe %q in group %q", pipe.ID, group.ID)
+		}
+		changed = true
+	}
+
+	for _, staleFlowID := range staleFlows(existingFlows, group.Flows) {
+		logger.Infof("removing stale sync flow %q from group %q", staleFlowID, group.ID)
+		if _, err := object.RunAdminCommandNoRealm(objContext, "sync", "group", "flow", "remove", realmArg, zoneGroupArg, groupIDArg, fmt.Sprintf("--flow-id=%s", staleFlowID)); err != nil {
+			return false, errors.Wrapf(err, "failed to remove stale sync flow %q in group %q", staleFlowID, group.ID)
+		}
+		changed = true
+	}
+
+	for _, stalePipeID := range stalePipes(existingPipes, group.Pipes) {
+		logger.Infof("removing stale sync pipe %q from group %q", stalePipeID, group.ID)
+		if _, err := object.RunAdminCommandNoRealm(objContext, "sync", "group", "pipe", "remove", realmArg, zoneGroupArg, groupIDArg, fmt.Sprintf("--pipe-id=%s", stalePipeID)); err != nil {
+			return false, errors.Wrapf(err, "failed to remove stale sync pipe %q in group %q", stalePipeID, group.ID)
+		}
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// flowUpToDate reports whether the desired flow is already present on the cluster with the same
+// type and endpoints, so reconcileSyncGroup can skip re-running `sync group flow create` (and,
+// transitively, avoid forcing an unnecessary period commit) on every reconcile.
+func flowUpToDate(existing []existingSyncFlow, flow cephv1.SyncFlowSpec) bool {
+	for _, e := range existing {
+		if e.ID == flow.ID {
+			return e.Type == flow.Type && e.Source == flow.SourceZone && e.Dest == flow.DestZone
+		}
+	}
+	return false
+}
+
+// pipeUpToDate reports whether the desired pipe is already present on the cluster with the same
+// source/dest zones and bucket scope.
+func pipeUpToDate(existing []existingSyncPipe, pipe cephv1.SyncPipeSpec) bool {
+	for _, e := range existing {
+		if e.ID == pipe.ID {
+			return e.Source.Zone == pipe.Source.Zones && e.Source.Bucket == pipe.Source.Bucket &&
+				e.Dest.Zone == pipe.Dest.Zones && e.Dest.Bucket == pipe.Dest.Bucket
+		}
+	}
+	return false
+}
+
+func getExistingSyncGroups(objContext *object.Context, realmArg, zoneGroupArg string) ([]existingSyncGroup, error) {
+	output, err := object.RunAdminCommandNoRealm(objContext, "sync", "group", "get", realmArg, zoneGroupArg)
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			// no sync policy configured yet is not an error, just an empty set of groups
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "radosgw-admin sync group get failed")
+	}
+
+	var groups []existingSyncGroup
+	if err := json.Unmarshal([]byte(output), &groups); err != nil {
+		return nil, errors.Wrap(err, "failed to parse `radosgw-admin sync group get` output")
+	}
+	return groups, nil
+}
+
+func findSyncGroup(existing []existingSyncGroup, id string) *existingSyncGroup {
+	for i := range existing {
+		if existing[i].ID == id {
+			return &existing[i]
+		}
+	}
+	return nil
+}
+
+func staleSyncGroups(existing []existingSyncGroup, desired []cephv1.SyncPolicyGroupSpec) []string {
+	stale := []string{}
+	for _, e := range existing {
+		found := false
+		for _, d := range desired {
+			if d.ID == e.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			stale = append(stale, e.ID)
+		}
+	}
+	return stale
+}
+
+// staleFlows returns the IDs of existing flows that are no longer present in desired, so a flow
+// removed from spec.syncPolicy.groups[].flows gets removed from the cluster instead of being left
+// active indefinitely.
+func staleFlows(existing []existingSyncFlow, desired []cephv1.SyncFlowSpec) []string {
+	stale := []string{}
+	for _, e := range existing {
+		found := false
+		for _, d := range desired {
+			if d.ID == e.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			stale = append(stale, e.ID)
+		}
+	}
+	return stale
+}
+
+// stalePipes returns the IDs of existing pipes that are no longer present in desired, so a pipe
+// removed from spec.syncPolicy.groups[].pipes gets removed from the cluster instead of being left
+// active indefinitely.
+func stalePipes(existing []existingSyncPipe, desired []cephv1.SyncPipeSpec) []string {
+	stale := []string{}
+	for _, e := range existing {
+		found := false
+		for _, d := range desired {
+			if d.ID == e.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			stale = append(stale, e.ID)
+		}
+	}
+	return stale
+}
+
+// updateSyncPolicyStatus records the enabled/allowed/forbidden state of each sync group so users
+// can observe replication topology from the CR without shelling into a toolbox pod. Each entry
+// carries a group's status as given in groups; when groups is empty, a single entry with an empty
+// ID and fallbackStatus is recorded instead.
+func (r *ReconcileObjectZone) updateSyncPolicyStatus(zone *cephv1.CephObjectZone, groups []cephv1.SyncPolicyGroupSpec, fallbackStatus string) {
+	name := types.NamespacedName{Namespace: zone.Namespace, Name: zone.Name}
+	current := &cephv1.CephObjectZone{}
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		logger.Warningf("failed to retrieve object zone %q to update sync policy status. %v", name, err)
+		return
+	}
+	if current.Status == nil {
+		current.Status = &cephv1.Status{}
+	}
+
+	statuses := make([]cephv1.SyncPolicyGroupStatus, 0, len(groups))
+	for _, group := range groups {
+		statuses = append(statuses, cephv1.SyncPolicyGroupStatus{ID: group.ID, State: group.Status})
+	}
+	if len(statuses) == 0 {
+		statuses = append(statuses, cephv1.SyncPolicyGroupStatus{State: fallbackStatus})
+	}
+	current.Status.SyncPolicyGroups = statuses
+
+	if err := opcontroller.UpdateStatus(r.client, current); err != nil {
+		logger.Errorf("failed to set object zone %q sync policy status. %v", name, err)
+	}
+}
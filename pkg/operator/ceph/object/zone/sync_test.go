@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zone
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlowUpToDate(t *testing.T) {
+	existing := []existingSyncFlow{
+		{ID: "flow-a", Type: "symmetrical", Source: "zone-a", Dest: "zone-b"},
+	}
+
+	assert.True(t, flowUpToDate(existing, cephv1.SyncFlowSpec{ID: "flow-a", Type: "symmetrical", SourceZone: "zone-a", DestZone: "zone-b"}))
+	assert.False(t, flowUpToDate(existing, cephv1.SyncFlowSpec{ID: "flow-a", Type: "directional", SourceZone: "zone-a", DestZone: "zone-b"}))
+	assert.False(t, flowUpToDate(existing, cephv1.SyncFlowSpec{ID: "flow-b", Type: "symmetrical", SourceZone: "zone-a", DestZone: "zone-b"}))
+}
+
+func TestPipeUpToDate(t *testing.T) {
+	existing := []existingSyncPipe{
+		{ID: "pipe-a", Source: existingSyncPipeEnd{Zone: "zone-a", Bucket: "bucket-a"}, Dest: existingSyncPipeEnd{Zone: "zone-b"}},
+	}
+
+	upToDate := cephv1.SyncPipeSpec{
+		ID:     "pipe-a",
+		Source: cephv1.SyncPipePeerSpec{Zones: "zone-a", Bucket: "bucket-a"},
+		Dest:   cephv1.SyncPipePeerSpec{Zones: "zone-b"},
+	}
+	assert.True(t, pipeUpToDate(existing, upToDate))
+
+	changedBucket := upToDate
+	changedBucket.Source.Bucket = "bucket-b"
+	assert.False(t, pipeUpToDate(existing, changedBucket))
+
+	assert.False(t, pipeUpToDate(existing, cephv1.SyncPipeSpec{ID: "pipe-b"}))
+}
+
+func TestStaleSyncGroups(t *testing.T) {
+	existing := []existingSyncGroup{{ID: "keep"}, {ID: "drop"}}
+	desired := []cephv1.SyncPolicyGroupSpec{{ID: "keep"}}
+
+	assert.Equal(t, []string{"drop"}, staleSyncGroups(existing, desired))
+}
+
+func TestStaleFlows(t *testing.T) {
+	existing := []existingSyncFlow{{ID: "keep"}, {ID: "drop"}}
+	desired := []cephv1.SyncFlowSpec{{ID: "keep"}}
+
+	assert.Equal(t, []string{"drop"}, staleFlows(existing, desired))
+}
+
+func TestStalePipes(t *testing.T) {
+	existing := []existingSyncPipe{{ID: "keep"}, {ID: "drop"}}
+	desired := []cephv1.SyncPipeSpec{{ID: "keep"}}
+
+	assert.Equal(t, []string{"drop"}, stalePipes(existing, desired))
+}